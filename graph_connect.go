@@ -32,6 +32,12 @@ type connection struct {
 	tgt     address
 	channel reflect.Value
 	buffer  int
+	// recvChannel is set only when the receiver's port is bound to a
+	// different channel than channel, e.g. a routed or replayed connection
+	// where the sender writes to one shared channel and each receiver reads
+	// from its own. Disconnect uses it to zero and ref-count the receiver's
+	// port independently of the sender's.
+	recvChannel reflect.Value
 }
 
 // Connect connects a sender to a receiver and creates a channel between them using BufferSize configuratio nof the graph.
@@ -45,6 +51,10 @@ func (n *Graph) Connect(senderName, senderPort, receiverName, receiverPort strin
 // ConnectBuf connects a sender to a receiver using a channel with a buffer of a given size.
 // It returns true on success or panics and returns false if error occurs.
 func (n *Graph) ConnectBuf(senderName, senderPort, receiverName, receiverPort string, bufferSize int) error {
+	if remoteName, remotePort, ok := isRemoteAddr(receiverName); ok {
+		return n.connectRemoteReceiver(senderName, senderPort, remoteName, remotePort, bufferSize)
+	}
+
 	sendAddr := parseAddress(senderName, senderPort)
 	sendPort, err := n.getProcPort(senderName, senderPort, reflect.SendDir)
 	if err != nil {
@@ -57,6 +67,10 @@ func (n *Graph) ConnectBuf(senderName, senderPort, receiverName, receiverPort st
 		return fmt.Errorf("connect: %w", err)
 	}
 
+	if f, ok := n.routedFanout(sendAddr); ok {
+		return n.connectRouted(f, senderName, senderPort, sendAddr, sendPort, receiverName, receiverPort, recvAddr, recvPort, bufferSize)
+	}
+
 	isNewChan := false // tells if a new channel will need to be created for this connection
 	// Try to find an existing outbound channel from the same sender,
 	// so it can be used as fan-out FIFO
@@ -261,43 +275,140 @@ func (n *Graph) decChanListenersCount(c reflect.Value) bool {
 	return cnt == 0
 }
 
-// // Disconnect removes a connection between sender's outport and receiver's inport.
-// func (n *Graph) Disconnect(senderName, senderPort, receiverName, receiverPort string) bool {
-// 	var sender, receiver interface{}
-// 	var ok bool
-// 	sender, ok = n.procs[senderName]
-// 	if !ok {
-// 		return false
-// 	}
-// 	receiver, ok = n.procs[receiverName]
-// 	if !ok {
-// 		return false
-// 	}
-// 	res := unsetProcPort(sender, senderPort, true)
-// 	res = res && unsetProcPort(receiver, receiverPort, false)
-// 	return res
-// }
-
-// // Unsets an port of a given process
-// func unsetProcPort(proc interface{}, portName string, isOut bool) bool {
-// 	v := reflect.ValueOf(proc)
-// 	var ch reflect.Value
-// 	if v.Elem().FieldByName("Graph").IsValid() {
-// 		if subnet, ok := v.Elem().FieldByName("Graph").Addr().Interface().(*Graph); ok {
-// 			if isOut {
-// 				ch = subnet.getOutPort(portName)
-// 			} else {
-// 				ch = subnet.getInPort(portName)
-// 			}
-// 		} else {
-// 			return false
-// 		}
-// 	} else {
-// 		ch = v.Elem().FieldByName(portName)
-// 	}
-// 	if !ch.IsValid() {
-// 		return false
-// 	}
-// 	ch.Set(reflect.Zero(ch.Type()))
-// 	return true
-// }
+// Disconnect removes a connection between sender's outport and receiver's inport.
+// It decrements the shared channel's listener count and only zeroes the sender's
+// and receiver's port fields once that count reaches 0, so a channel that is still
+// fanned out or fanned in to other processes is left running. When the connection's
+// receiver was bound to its own channel (conn.recvChannel, set by a routed or
+// replayed connection), that channel is ref-counted and zeroed independently of the
+// sender's, since the two are no longer the same reflect.Value.
+func (n *Graph) Disconnect(senderName, senderPort, receiverName, receiverPort string) error {
+	sendAddr := parseAddress(senderName, senderPort)
+	recvAddr := parseAddress(receiverName, receiverPort)
+	// A remote receiver ("remote/port", as passed to Connect) is recorded under
+	// its remote name and port, and has no local port field to zero.
+	remoteName, remotePort, isRemote := isRemoteAddr(receiverName)
+	if isRemote {
+		recvAddr = address{proc: remoteName, port: remotePort}
+	}
+
+	idx, conn, err := n.findConnection(sendAddr, recvAddr)
+	if err != nil {
+		return fmt.Errorf("disconnect: %w", err)
+	}
+
+	n.connections = append(n.connections[:idx], n.connections[idx+1:]...)
+
+	if n.decChanListenersCount(conn.channel) {
+		if err := n.zeroPort(senderName, senderPort, reflect.SendDir, conn.channel); err != nil {
+			return fmt.Errorf("disconnect '%s.%s': %w", senderName, senderPort, err)
+		}
+		if !conn.recvChannel.IsValid() && !isRemote {
+			if err := n.zeroPort(receiverName, receiverPort, reflect.RecvDir, conn.channel); err != nil {
+				return fmt.Errorf("disconnect '%s.%s': %w", receiverName, receiverPort, err)
+			}
+		}
+	}
+
+	if conn.recvChannel.IsValid() && n.decChanListenersCount(conn.recvChannel) {
+		if err := n.zeroPort(receiverName, receiverPort, reflect.RecvDir, conn.recvChannel); err != nil {
+			return fmt.Errorf("disconnect '%s.%s': %w", receiverName, receiverPort, err)
+		}
+	}
+
+	return nil
+}
+
+// Rewire swaps the sender of a running connection for a new one without dropping the
+// receiver or losing in-flight packets. It attaches a new outbound channel on the new
+// sender, drains whatever is still buffered on the old channel into it, then retires
+// the old sender's port the same way Disconnect does.
+func (n *Graph) Rewire(oldSenderName, oldSenderPort, newSenderName, newSenderPort, receiverName, receiverPort string) error {
+	oldSendAddr := parseAddress(oldSenderName, oldSenderPort)
+	recvAddr := parseAddress(receiverName, receiverPort)
+
+	idx, conn, err := n.findConnection(oldSendAddr, recvAddr)
+	if err != nil {
+		return fmt.Errorf("rewire: %w", err)
+	}
+
+	newSendAddr := parseAddress(newSenderName, newSenderPort)
+	newSendPort, err := n.getProcPort(newSenderName, newSenderPort, reflect.SendDir)
+	if err != nil {
+		return fmt.Errorf("rewire: %w", err)
+	}
+
+	newCh := n.findExistingChan(newSendAddr, reflect.SendDir)
+	newCh, err = attachPort(newSendPort, reflect.SendDir, newCh, conn.buffer)
+	if err != nil {
+		return fmt.Errorf("rewire '%s.%s': %w", newSenderName, newSenderPort, err)
+	}
+	n.incChanListenersCount(newCh)
+
+	// Move whatever is still buffered on the old channel onto the new one before
+	// the receiver can notice the swap, so no in-flight packets are lost.
+	drainChan(conn.channel, newCh)
+
+	// Repoint the receiver's port at newCh too: otherwise it keeps reading the old
+	// channel forever, never sees the drained history or anything the new sender
+	// sends, and newCh itself fills up and blocks the new sender once its buffer
+	// is exhausted.
+	recvPort, err := n.getProcPort(receiverName, receiverPort, reflect.RecvDir)
+	if err != nil {
+		return fmt.Errorf("rewire: %w", err)
+	}
+	if _, err := attachPort(recvPort, reflect.RecvDir, newCh, conn.buffer); err != nil {
+		return fmt.Errorf("rewire '%s.%s': %w", receiverName, receiverPort, err)
+	}
+
+	if n.decChanListenersCount(conn.channel) {
+		if err := n.zeroPort(oldSenderName, oldSenderPort, reflect.SendDir, conn.channel); err != nil {
+			return fmt.Errorf("rewire '%s.%s': %w", oldSenderName, oldSenderPort, err)
+		}
+	}
+
+	n.connections[idx] = connection{
+		src:     newSendAddr,
+		tgt:     recvAddr,
+		channel: newCh,
+		buffer:  conn.buffer,
+	}
+
+	return nil
+}
+
+// findConnection returns the index and value of the connection matching src and tgt.
+func (n *Graph) findConnection(src, tgt address) (int, connection, error) {
+	for i, c := range n.connections {
+		if c.src == src && c.tgt == tgt {
+			return i, c, nil
+		}
+	}
+	return -1, connection{}, fmt.Errorf("connection '%s.%s' -> '%s.%s' not found", src.proc, src.port, tgt.proc, tgt.port)
+}
+
+// zeroPort clears a process's port field, but only if it still points at ch. This
+// guards against clobbering a port that has since been rewired onto another channel.
+func (n *Graph) zeroPort(procName, portName string, dir reflect.ChanDir, ch reflect.Value) error {
+	portVal, err := n.getProcPort(procName, portName, dir)
+	if err != nil {
+		return err
+	}
+	if portVal.Pointer() == ch.Pointer() {
+		portVal.Set(reflect.Zero(portVal.Type()))
+	}
+	return nil
+}
+
+// drainChan moves every value currently buffered on src onto dst, preserving order.
+// Receiving from src never blocks: it stops as soon as src has nothing more ready
+// to receive. Each Send onto dst blocks until dst has room for the value.
+func drainChan(src, dst reflect.Value) {
+	for {
+		v, ok := src.TryRecv()
+		if !ok {
+			return
+		}
+		dst.Send(v)
+	}
+}
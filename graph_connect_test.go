@@ -0,0 +1,42 @@
+package goflow
+
+import "testing"
+
+type rewireSender struct {
+	Out chan string
+}
+
+type rewireReceiver struct {
+	In chan string
+}
+
+func TestRewireDeliversDrainedAndNewSenderPackets(t *testing.T) {
+	n := newTestGraph()
+	n.addProc("oldSender", &rewireSender{})
+	n.addProc("newSender", &rewireSender{})
+	n.addProc("receiver", &rewireReceiver{})
+
+	if err := n.ConnectBuf("oldSender", "Out", "receiver", "In", 4); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	oldSender := n.procs["oldSender"].(*rewireSender)
+	newSender := n.procs["newSender"].(*rewireSender)
+	receiver := n.procs["receiver"].(*rewireReceiver)
+
+	// Buffered on the old channel before the swap; Rewire must carry it
+	// across onto the new sender's channel rather than stranding it.
+	oldSender.Out <- "drained"
+
+	if err := n.Rewire("oldSender", "Out", "newSender", "Out", "receiver", "In"); err != nil {
+		t.Fatalf("rewire: %v", err)
+	}
+
+	newSender.Out <- "fresh"
+
+	first := <-receiver.In
+	second := <-receiver.In
+	if first != "drained" || second != "fresh" {
+		t.Fatalf("receiver got (%q, %q), want (\"drained\", \"fresh\") — Rewire must repoint the receiver's port at the new channel, not just the new sender's", first, second)
+	}
+}
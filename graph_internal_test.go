@@ -0,0 +1,17 @@
+package goflow
+
+// newTestGraph returns an empty Graph suitable for wiring up test processes,
+// with just enough internal state initialized for Connect/Disconnect/Rewire
+// and the routing/replay/tap extensions to operate on.
+func newTestGraph() *Graph {
+	return &Graph{
+		procs:              map[string]interface{}{},
+		chanListenersCount: map[uintptr]int{},
+	}
+}
+
+// addProc registers proc (always a pointer, so its exported channel fields
+// are settable via reflection) under name.
+func (n *Graph) addProc(name string, proc interface{}) {
+	n.procs[name] = proc
+}
@@ -0,0 +1,204 @@
+package goflow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/codelity-co/goflow/remote"
+)
+
+// remotesLock guards remotes. Endpoints are tracked per-Graph in a
+// package-level map rather than as a Graph field, so every Connect call can
+// resolve a "name/port" address without threading extra state through
+// attachPort.
+var remotesLock sync.Mutex
+var remotes = make(map[*Graph]map[string]*remote.Endpoint)
+
+// AddRemote registers a remote goflow host so that a later Connect can
+// address one of its ports as "name/port". It does not dial the peer; the
+// gRPC stream is opened lazily the first time a connection needs it.
+func (n *Graph) AddRemote(name, addr string) error {
+	remotesLock.Lock()
+	defer remotesLock.Unlock()
+
+	hosts, ok := remotes[n]
+	if !ok {
+		hosts = make(map[string]*remote.Endpoint)
+		remotes[n] = hosts
+	}
+	if _, ok := hosts[name]; ok {
+		return fmt.Errorf("remote '%s' already registered", name)
+	}
+	hosts[name] = remote.NewEndpoint(name, addr)
+	return nil
+}
+
+// connectRemoteReceiver implements the "localProc", "Out", "remoteProc/InPort"
+// form of Connect: it attaches senderPort to a proxy channel backed by a gRPC
+// stream to the remote endpoint, and records the result in n.connections
+// like any other connection so Disconnect and the listener-count bookkeeping
+// work unchanged.
+func (n *Graph) connectRemoteReceiver(senderName, senderPort, remoteName, remotePort string, bufferSize int) error {
+	sendAddr := parseAddress(senderName, senderPort)
+	sendPort, err := n.getProcPort(senderName, senderPort, reflect.SendDir)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+
+	ch, err := n.connectRemoteChan(remoteName, remotePort, sendPort.Type().Elem(), reflect.SendDir, bufferSize)
+	if err != nil {
+		return fmt.Errorf("connect '%s.%s': %w", senderName, senderPort, err)
+	}
+	if err := validateChanDir(sendPort.Type(), reflect.SendDir); err != nil {
+		return fmt.Errorf("connect '%s.%s': %w", senderName, senderPort, err)
+	}
+	if err := validateCanSet(sendPort); err != nil {
+		return fmt.Errorf("connect '%s.%s': %w", senderName, senderPort, err)
+	}
+	sendPort.Set(ch)
+	n.incChanListenersCount(ch)
+
+	n.connections = append(n.connections, connection{
+		src:     sendAddr,
+		tgt:     address{proc: remoteName, port: remotePort},
+		channel: ch,
+		buffer:  bufferSize,
+	})
+	return nil
+}
+
+func (n *Graph) getRemote(name string) (*remote.Endpoint, bool) {
+	remotesLock.Lock()
+	defer remotesLock.Unlock()
+	ep, ok := remotes[n][name]
+	return ep, ok
+}
+
+// isRemoteAddr reports whether procName has the "remoteName/portName" form
+// that Connect uses to target a remote process, splitting it into its parts.
+func isRemoteAddr(procName string) (remoteName, portName string, ok bool) {
+	i := strings.IndexByte(procName, '/')
+	if i < 0 {
+		return "", "", false
+	}
+	return procName[:i], procName[i+1:], true
+}
+
+// remotePort is the subset of *remote.Port (and *remote.SecurePort, which
+// embeds it) that proxyChan needs, so the proxy goroutine doesn't care
+// whether the stream underneath is plaintext or end-to-end encrypted.
+type remotePort interface {
+	Send(reflect.Value) error
+	Recv(reflect.Type) (reflect.Value, error)
+	Close() error
+}
+
+// connectRemoteChan opens a gRPC-backed proxy channel to remoteName/remotePort
+// and starts the goroutine that pumps values between it and the local end of
+// the connection, the same role a plain channel plays for two local
+// processes. elemType is the local port's element type, which the remote
+// value is decoded into (or encoded from).
+func (n *Graph) connectRemoteChan(remoteName, remotePort string, elemType reflect.Type, dir reflect.ChanDir, bufSize int) (reflect.Value, error) {
+	ep, ok := n.getRemote(remoteName)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("remote '%s' not registered, call Graph.AddRemote first", remoteName)
+	}
+
+	port, err := ep.OpenPort(context.Background(), remotePort)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("remote '%s/%s': %w", remoteName, remotePort, err)
+	}
+
+	return proxyChan(port, elemType, dir, bufSize), nil
+}
+
+// proxyChan wires a remotePort to a fresh local channel of elemType, running
+// the goroutine that relays values between them until either side closes.
+func proxyChan(port remotePort, elemType reflect.Type, dir reflect.ChanDir, bufSize int) reflect.Value {
+	ch := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, elemType), bufSize)
+
+	if dir == reflect.SendDir {
+		// The local sender writes to ch; forward every value it produces
+		// across the stream to the remote receiver.
+		go func() {
+			for {
+				v, ok := ch.Recv()
+				if !ok {
+					port.Close()
+					return
+				}
+				if err := port.Send(v); err != nil {
+					return
+				}
+			}
+		}()
+	} else {
+		// Forward every value the remote sender produces onto ch, where the
+		// local receiver reads it.
+		go func() {
+			for {
+				v, err := port.Recv(elemType)
+				if err != nil {
+					ch.Close()
+					return
+				}
+				ch.Send(v)
+			}
+		}()
+	}
+
+	return ch
+}
+
+// ExposeRemote registers recvPort of receiverName as a port that remote
+// graphs may connect to by name, adding a handler to srv that decodes each
+// incoming frame and delivers it to the process' existing channel exactly
+// like a local sender would. A closed remote stream decrements the port's
+// chanListenersCount the same way Disconnect does, so the last remote peer
+// leaving lets the channel be retired instead of leaking its listener count.
+func (n *Graph) ExposeRemote(srv *remote.Server, receiverName, receiverPort string) error {
+	recvPort, err := n.getProcPort(receiverName, receiverPort, reflect.RecvDir)
+	if err != nil {
+		return fmt.Errorf("expose remote: %w", err)
+	}
+
+	// recvPort is the raw, unattached struct field: unlike every other entry
+	// point in this file, there is no local Connect call to have already
+	// allocated a channel for it, so the common case (a process whose only
+	// connection is this remote one) leaves it nil. Allocate one with
+	// attachPort like ConnectBuf does, reusing an existing local fan-in
+	// channel if one is already attached.
+	recvAddr := parseAddress(receiverName, receiverPort)
+	ch := n.findExistingChan(recvAddr, reflect.RecvDir)
+	isNewChan := !ch.IsValid() || ch.IsNil()
+	if !isNewChan {
+		n.incChanListenersCount(ch)
+	}
+	ch, err = attachPort(recvPort, reflect.RecvDir, ch, n.conf.BufferSize)
+	if err != nil {
+		return fmt.Errorf("expose remote '%s.%s': %w", receiverName, receiverPort, err)
+	}
+	if isNewChan {
+		n.incChanListenersCount(ch)
+	}
+	elemType := ch.Type().Elem()
+
+	srv.Handle(receiverPort, func(data []byte, closed bool) error {
+		if closed {
+			if n.decChanListenersCount(ch) {
+				ch.Close()
+			}
+			return nil
+		}
+		v, err := remote.DecodeValue(data, elemType)
+		if err != nil {
+			return err
+		}
+		ch.Send(v)
+		return nil
+	})
+	return nil
+}
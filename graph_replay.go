@@ -0,0 +1,173 @@
+package goflow
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// replays holds the ring-buffer state behind ConnectReplay, keyed by the
+// Graph it belongs to and the sender address it buffers. Like the fan-out
+// and remote registries, it lives outside the Graph struct because replay
+// is optional, out-of-band bookkeeping rather than core port wiring.
+var replaysLock sync.Mutex
+var replays = make(map[*Graph]map[address]*replay)
+
+// replay is the shared state for one sender address connected with
+// ConnectReplay: a single goroutine reads every packet off srcChan, records
+// it in a fixed-size ring buffer, and forwards it to every receiver
+// currently attached.
+type replay struct {
+	mu        sync.Mutex
+	history   []reflect.Value // ring buffer, oldest first
+	size      int
+	srcChan   reflect.Value
+	receivers []reflect.Value
+	started   bool
+}
+
+// ConnectReplay behaves like ConnectBuf, except the receiver is guaranteed
+// to see the last historySize packets the sender emitted, delivered before
+// any live traffic, even if it attaches long after the sender started
+// emitting. The first ConnectReplay call on a given sender address fixes the
+// buffer's size; later calls targeting the same sender just replay into a
+// new receiver.
+func (n *Graph) ConnectReplay(senderName, senderPort, receiverName, receiverPort string, historySize int) error {
+	sendAddr := parseAddress(senderName, senderPort)
+	sendPort, err := n.getProcPort(senderName, senderPort, reflect.SendDir)
+	if err != nil {
+		return fmt.Errorf("connect replay: %w", err)
+	}
+	recvPort, err := n.getProcPort(receiverName, receiverPort, reflect.RecvDir)
+	if err != nil {
+		return fmt.Errorf("connect replay: %w", err)
+	}
+
+	r := n.replayFor(sendAddr, historySize)
+
+	r.mu.Lock()
+	if !r.srcChan.IsValid() || r.srcChan.IsNil() {
+		ch, err := attachPort(sendPort, reflect.SendDir, reflect.Value{}, n.conf.BufferSize)
+		if err != nil {
+			r.mu.Unlock()
+			return fmt.Errorf("connect replay '%s.%s': %w", senderName, senderPort, err)
+		}
+		r.srcChan = ch
+		n.incChanListenersCount(ch)
+	}
+	// Snapshot history now, before releasing the lock: anything pump() adds
+	// to r.history after this point arrives as a live send once this
+	// receiver is registered below, not a replayed one.
+	history := append([]reflect.Value(nil), r.history...)
+	started := r.started
+	r.started = true
+	r.mu.Unlock()
+
+	recvChan, err := attachPort(recvPort, reflect.RecvDir, reflect.Value{}, n.conf.BufferSize)
+	if err != nil {
+		return fmt.Errorf("connect replay '%s.%s': %w", receiverName, receiverPort, err)
+	}
+	n.incChanListenersCount(recvChan)
+
+	if !started {
+		go r.pump()
+	}
+
+	// Drain the buffered history into the new receiver before it is added to
+	// the live fan-out. This must happen outside r.mu: a freshly attached
+	// receiver's channel is typically unbuffered and has no reader yet (the
+	// whole point of a late-joining receiver), so Send here can block for a
+	// while; holding r.mu across it would also freeze pump() (which needs
+	// the same lock to append to history) and any other ConnectReplay or
+	// Snapshot call for this sender.
+	for _, v := range history {
+		recvChan.Send(v)
+	}
+
+	r.mu.Lock()
+	r.receivers = append(r.receivers, recvChan)
+	r.mu.Unlock()
+
+	n.connections = append(n.connections, connection{
+		src:         sendAddr,
+		tgt:         parseAddress(receiverName, receiverPort),
+		channel:     r.srcChan,
+		recvChannel: recvChan,
+		buffer:      n.conf.BufferSize,
+	})
+	return nil
+}
+
+// Snapshot returns a copy of the replay buffer currently held for
+// senderAddr, formatted as "procName.portName", so tests can assert on what
+// a late-joining receiver would see. It returns nil if no ConnectReplay has
+// been made from that address.
+func (n *Graph) Snapshot(senderAddr string) []interface{} {
+	proc, port, ok := strings.Cut(senderAddr, ".")
+	if !ok {
+		return nil
+	}
+	addr := parseAddress(proc, port)
+
+	replaysLock.Lock()
+	r, ok := replays[n][addr]
+	replaysLock.Unlock()
+	if !ok {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]interface{}, len(r.history))
+	for i, v := range r.history {
+		out[i] = v.Interface()
+	}
+	return out
+}
+
+func (n *Graph) replayFor(addr address, historySize int) *replay {
+	replaysLock.Lock()
+	defer replaysLock.Unlock()
+
+	byAddr, ok := replays[n]
+	if !ok {
+		byAddr = make(map[address]*replay)
+		replays[n] = byAddr
+	}
+	r, ok := byAddr[addr]
+	if !ok {
+		r = &replay{size: historySize}
+		byAddr[addr] = r
+	}
+	return r
+}
+
+// pump is the replay goroutine: it reads every packet sent on srcChan,
+// appends it to the ring buffer (evicting the oldest entry once size is
+// reached), and forwards it to every receiver currently attached.
+func (r *replay) pump() {
+	for {
+		v, ok := r.srcChan.Recv()
+		if !ok {
+			r.mu.Lock()
+			for _, c := range r.receivers {
+				c.Close()
+			}
+			r.mu.Unlock()
+			return
+		}
+
+		r.mu.Lock()
+		r.history = append(r.history, v)
+		if len(r.history) > r.size {
+			r.history = r.history[len(r.history)-r.size:]
+		}
+		receivers := append([]reflect.Value(nil), r.receivers...)
+		r.mu.Unlock()
+
+		for _, c := range receivers {
+			c.Send(v)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package goflow
+
+import "testing"
+
+type replaySender struct {
+	Out chan int
+}
+
+type replayReceiver struct {
+	In chan int
+}
+
+func TestConnectReplayDeliversHistoryThenLiveSnapshot(t *testing.T) {
+	n := newTestGraph()
+	n.addProc("sender", &replaySender{})
+	n.addProc("early", &replayReceiver{})
+
+	if err := n.ConnectReplay("sender", "Out", "early", "In", 2); err != nil {
+		t.Fatalf("connect replay (early): %v", err)
+	}
+
+	sender := n.procs["sender"].(*replaySender)
+	early := n.procs["early"].(*replayReceiver)
+
+	// sender.Out and early.In are unbuffered (newTestGraph leaves BufferSize
+	// at its zero value), so sends must run concurrently with the reads that
+	// unblock pump() one packet at a time.
+	go func() {
+		sender.Out <- 1
+		sender.Out <- 2
+		sender.Out <- 3 // history size 2, so this evicts packet 1
+	}()
+
+	if got := <-early.In; got != 1 {
+		t.Fatalf("early.In = %d, want 1", got)
+	}
+	if got := <-early.In; got != 2 {
+		t.Fatalf("early.In = %d, want 2", got)
+	}
+	if got := <-early.In; got != 3 {
+		t.Fatalf("early.In = %d, want 3", got)
+	}
+
+	snap := n.Snapshot("sender.Out")
+	if len(snap) != 2 || snap[0] != 2 || snap[1] != 3 {
+		t.Fatalf("Snapshot() = %v, want [2 3]", snap)
+	}
+
+	// A late-joining receiver must see the buffered history before any new
+	// live packet, without ConnectReplay deadlocking while draining it. The
+	// drain happens synchronously inside ConnectReplay, so it needs a
+	// concurrent reader before it can return.
+	// ConnectReplay binds late.In inside the goroutine below; giving the port
+	// a channel up front (attachPort keeps a port's existing channel) lets the
+	// test read from it without waiting on that assignment.
+	lateIn := make(chan int)
+	n.addProc("late", &replayReceiver{In: lateIn})
+	connectErr := make(chan error, 1)
+	go func() {
+		connectErr <- n.ConnectReplay("sender", "Out", "late", "In", 2)
+	}()
+
+	if got := <-lateIn; got != 2 {
+		t.Fatalf("late.In first = %d, want 2 (replayed history)", got)
+	}
+	if got := <-lateIn; got != 3 {
+		t.Fatalf("late.In second = %d, want 3 (replayed history)", got)
+	}
+	if err := <-connectErr; err != nil {
+		t.Fatalf("connect replay (late): %v", err)
+	}
+
+	// pump() forwards to receivers in registration order (early, then late),
+	// sending to each in turn, so reads must follow the same order.
+	go func() { sender.Out <- 4 }()
+	if got := <-early.In; got != 4 {
+		t.Fatalf("early.In = %d, want 4 (live traffic after replay)", got)
+	}
+	if got := <-lateIn; got != 4 {
+		t.Fatalf("late.In = %d, want 4 (live traffic after replay)", got)
+	}
+}
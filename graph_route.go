@@ -0,0 +1,260 @@
+package goflow
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"reflect"
+	"sync"
+)
+
+// RouteMode selects how packets on a fanned-out sender port are distributed
+// among the receivers connected to it. The zero value, RouteBroadcast, is
+// goflow's original behavior: every receiver reads off one shared channel
+// and competes for each packet.
+type RouteMode int
+
+const (
+	// RouteBroadcast shares a single channel among all receivers, so any one
+	// of them may pick up any given packet. This is ConnectBuf's long-standing
+	// fan-out behavior.
+	RouteBroadcast RouteMode = iota
+	// RouteRoundRobin hands successive packets to receivers in turn.
+	RouteRoundRobin
+	// RouteAffinity routes by the port's registered key function: packets
+	// whose extracted key has been seen before keep going to the same
+	// receiver they were first routed to.
+	RouteAffinity
+	// RouteRandom picks a receiver for each packet independently at random.
+	RouteRandom
+)
+
+// routing holds the fan-out dispatcher state registered with SetKeyFunc and
+// SetRouteMode, keyed by the Graph it belongs to and the sender address it
+// routes. It lives outside the Graph struct for the same reason the remote
+// registry does: connection routing is orthogonal, out-of-band bookkeeping
+// rather than core port wiring.
+var routingLock sync.Mutex
+var routing = make(map[*Graph]map[address]*fanout)
+
+// fanout is the shared dispatcher state for one sender address once it has
+// more than one receiver: a single goroutine reads srcChan and forwards each
+// packet to exactly one of receivers, chosen according to mode.
+type fanout struct {
+	mu        sync.Mutex
+	mode      RouteMode
+	keyFn     func(interface{}) string
+	srcChan   reflect.Value
+	receivers []reflect.Value
+	affinity  map[string]int // routing key -> index into receivers
+	next      int            // next receiver index for RouteRoundRobin
+	started   bool
+}
+
+// SetKeyFunc registers fn as the routing key extractor for procName's
+// portName, switching that port's fan-out from broadcast to affinity
+// routing: once two or more Connect calls share this sender address, all
+// packets whose fn(packet) returns the same key are delivered to the same
+// receiver. It may be called any time before the port's second receiver
+// connects, whether or not the first Connect has already happened: if a
+// plain, non-routed connection already exists for this sender, connectRouted
+// migrates it onto its own receiver channel the next time a second receiver
+// connects.
+func (n *Graph) SetKeyFunc(procName, portName string, fn func(interface{}) string) {
+	addr := parseAddress(procName, portName)
+	f := n.fanoutFor(addr, RouteAffinity)
+	f.mu.Lock()
+	f.keyFn = fn
+	f.mu.Unlock()
+}
+
+// SetRouteMode registers mode as the fan-out policy for procName's portName,
+// for RouteRoundRobin and RouteRandom where no key function is needed. Like
+// SetKeyFunc, it may be called any time before the port's second receiver
+// connects.
+func (n *Graph) SetRouteMode(procName, portName string, mode RouteMode) {
+	n.fanoutFor(parseAddress(procName, portName), mode)
+}
+
+// fanoutFor returns the fanout registered for addr, creating it with mode if
+// this is the first call, and always applying mode to the (possibly
+// pre-existing) fanout before returning it: SetKeyFunc and SetRouteMode can
+// be called in either order for the same port, and the later call's mode
+// must win rather than being silently ignored because a fanout already
+// existed.
+func (n *Graph) fanoutFor(addr address, mode RouteMode) *fanout {
+	routingLock.Lock()
+	byAddr, ok := routing[n]
+	if !ok {
+		byAddr = make(map[address]*fanout)
+		routing[n] = byAddr
+	}
+	f, ok := byAddr[addr]
+	if !ok {
+		f = &fanout{mode: mode, affinity: make(map[string]int)}
+		byAddr[addr] = f
+	}
+	routingLock.Unlock()
+
+	f.mu.Lock()
+	f.mode = mode
+	f.mu.Unlock()
+	return f
+}
+
+func (n *Graph) routedFanout(addr address) (*fanout, bool) {
+	routingLock.Lock()
+	defer routingLock.Unlock()
+	f, ok := routing[n][addr]
+	return f, ok && f.mode != RouteBroadcast
+}
+
+// connectRouted attaches recvPort as one more receiver of sendAddr's routed
+// fan-out, creating the dispatcher goroutine on the fanout's first
+// connectRouted call. Each receiver gets its own channel rather than sharing the
+// sender's, so the dispatcher can steer individual packets instead of
+// leaving delivery to channel-read contention.
+func (n *Graph) connectRouted(f *fanout, senderName, senderPort string, sendAddr address, sendPort reflect.Value, receiverName, receiverPort string, recvAddr address, recvPort reflect.Value, bufferSize int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.srcChan.IsValid() || f.srcChan.IsNil() {
+		if idx, direct, ok := n.findDirectConnection(sendAddr); ok {
+			// A plain Connect already wired sendAddr straight to a receiver
+			// before routing was enabled on it: that receiver currently reads
+			// the exact channel sendPort is bound to. Leave sendPort alone,
+			// but move the receiver onto its own channel and into
+			// f.receivers, so dispatch() becomes the only reader of
+			// direct.channel instead of racing the receiver for packets
+			// outside the routing policy.
+			migratedPort, err := n.getProcPort(direct.tgt.proc, direct.tgt.port, reflect.RecvDir)
+			if err != nil {
+				return fmt.Errorf("connect '%s.%s': %w", senderName, senderPort, err)
+			}
+			// Pass a fresh channel: attachPort keeps a port's existing
+			// channel when given none, which would leave the receiver on
+			// direct.channel.
+			freshChan := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, migratedPort.Type().Elem()), bufferSize)
+			migratedChan, err := attachPort(migratedPort, reflect.RecvDir, freshChan, bufferSize)
+			if err != nil {
+				return fmt.Errorf("connect '%s.%s': %w", senderName, senderPort, err)
+			}
+			n.incChanListenersCount(migratedChan)
+
+			f.srcChan = direct.channel
+			f.receivers = append(f.receivers, migratedChan)
+			n.connections[idx] = connection{
+				src:         direct.src,
+				tgt:         direct.tgt,
+				channel:     direct.channel,
+				recvChannel: migratedChan,
+				buffer:      direct.buffer,
+			}
+		} else {
+			ch, err := attachPort(sendPort, reflect.SendDir, reflect.Value{}, bufferSize)
+			if err != nil {
+				return fmt.Errorf("connect '%s.%s': %w", senderName, senderPort, err)
+			}
+			f.srcChan = ch
+			n.incChanListenersCount(ch)
+		}
+	}
+
+	recvChan, err := attachPort(recvPort, reflect.RecvDir, reflect.Value{}, bufferSize)
+	if err != nil {
+		return fmt.Errorf("connect '%s.%s': %w", receiverName, receiverPort, err)
+	}
+	f.receivers = append(f.receivers, recvChan)
+	n.incChanListenersCount(recvChan)
+
+	if !f.started {
+		f.started = true
+		go f.dispatch()
+	}
+
+	n.connections = append(n.connections, connection{
+		src:         sendAddr,
+		tgt:         recvAddr,
+		channel:     f.srcChan,
+		recvChannel: recvChan,
+		buffer:      bufferSize,
+	})
+	return nil
+}
+
+// findDirectConnection returns the connection a plain, non-routed Connect
+// made for sendAddr, if any: one whose receiver still reads the sender's
+// channel directly rather than its own dedicated recvChannel.
+func (n *Graph) findDirectConnection(sendAddr address) (int, connection, bool) {
+	for i, c := range n.connections {
+		if c.src == sendAddr && !c.recvChannel.IsValid() {
+			return i, c, true
+		}
+	}
+	return -1, connection{}, false
+}
+
+// dispatch is the fan-out goroutine: it reads every packet sent on srcChan
+// and forwards it to whichever receiver the routing mode selects, until
+// srcChan is closed, at which point it closes every receiver in turn.
+func (f *fanout) dispatch() {
+	for {
+		v, ok := f.srcChan.Recv()
+		if !ok {
+			f.mu.Lock()
+			for _, r := range f.receivers {
+				r.Close()
+			}
+			f.mu.Unlock()
+			return
+		}
+		f.route(v).Send(v)
+	}
+}
+
+// route picks the receiver channel for v under the fanout's mode. It only
+// holds f.mu while choosing the index, not while sending: Send on the
+// chosen channel can block, and the lock only needs to protect the shared
+// round-robin/affinity bookkeeping.
+func (f *fanout) route(v reflect.Value) reflect.Value {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch f.mode {
+	case RouteRoundRobin:
+		r := f.receivers[f.next%len(f.receivers)]
+		f.next++
+		return r
+	case RouteRandom:
+		return f.receivers[randIndex(len(f.receivers))]
+	case RouteAffinity:
+		key := f.keyFn(v.Interface())
+		idx, ok := f.affinity[key]
+		if !ok {
+			idx = hashIndex(key, len(f.receivers))
+			f.affinity[key] = idx
+		}
+		return f.receivers[idx]
+	default: // RouteBroadcast: still dispatched through the goroutine, but
+		// sent to the least-recently-used receiver so delivery stays even.
+		r := f.receivers[f.next%len(f.receivers)]
+		f.next++
+		return r
+	}
+}
+
+// hashIndex deterministically maps key onto one of n receivers, giving a new
+// key its initial affinity assignment.
+func hashIndex(key string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32()) % n
+}
+
+// randIndex is a package variable so tests can make RouteRandom
+// deterministic; it defaults to a real pseudo-random index.
+var randIndex = defaultRandIndex
+
+func defaultRandIndex(n int) int {
+	return rand.Intn(n)
+}
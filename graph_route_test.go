@@ -0,0 +1,70 @@
+package goflow
+
+import "testing"
+
+type routeSender struct {
+	Out chan int
+}
+
+type routeReceiver struct {
+	In chan int
+}
+
+func TestSetRouteModeOverridesExistingFanout(t *testing.T) {
+	n := newTestGraph()
+	addr := parseAddress("p", "Out")
+
+	n.SetKeyFunc("p", "Out", func(interface{}) string { return "k" })
+	n.SetRouteMode("p", "Out", RouteRoundRobin)
+
+	f, ok := n.routedFanout(addr)
+	if !ok {
+		t.Fatal("expected routing to be enabled after SetRouteMode")
+	}
+	if f.mode != RouteRoundRobin {
+		t.Fatalf("mode = %v, want RouteRoundRobin; SetRouteMode must win over an already-registered fanout's mode, not silently no-op", f.mode)
+	}
+}
+
+func TestConnectRoutedMigratesExistingDirectReceiver(t *testing.T) {
+	n := newTestGraph()
+	n.addProc("sender", &routeSender{})
+	n.addProc("r1", &routeReceiver{})
+	n.addProc("r2", &routeReceiver{})
+
+	// r1 connects before routing is enabled on "sender.Out": a plain,
+	// unrouted connection where r1 reads the sender's channel directly.
+	if err := n.Connect("sender", "Out", "r1", "In"); err != nil {
+		t.Fatalf("connect r1: %v", err)
+	}
+
+	n.SetRouteMode("sender", "Out", RouteRoundRobin)
+
+	// r2 is the second receiver, triggering connectRouted and, with it,
+	// migration of r1 off the shared channel and into the fan-out.
+	if err := n.Connect("sender", "Out", "r2", "In"); err != nil {
+		t.Fatalf("connect r2: %v", err)
+	}
+
+	sender := n.procs["sender"].(*routeSender)
+	r1 := n.procs["r1"].(*routeReceiver)
+	r2 := n.procs["r2"].(*routeReceiver)
+
+	go func() {
+		sender.Out <- 1
+		sender.Out <- 2
+	}()
+
+	got := map[int]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case v := <-r1.In:
+			got[v] = true
+		case v := <-r2.In:
+			got[v] = true
+		}
+	}
+	if !got[1] || !got[2] {
+		t.Fatalf("expected both packets delivered exactly once via round-robin routing, got %v — r1 racing the dispatcher for the raw shared channel would instead let it steal packets outside the routing policy", got)
+	}
+}
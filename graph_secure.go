@@ -0,0 +1,117 @@
+package goflow
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// identities holds each Graph's own Ed25519 identity and the public keys it
+// trusts for named remote peers, set with SetIdentity and TrustPeer. Like
+// the remote and routing registries, it lives outside the Graph struct
+// because it's optional security configuration rather than core port
+// wiring.
+var identitiesLock sync.Mutex
+var identities = make(map[*Graph]*identity)
+
+type identity struct {
+	priv  ed25519.PrivateKey
+	trust map[string]ed25519.PublicKey
+}
+
+// SetIdentity sets the long-term Ed25519 identity this graph presents
+// during a ConnectSecure handshake.
+func (n *Graph) SetIdentity(priv ed25519.PrivateKey) {
+	identitiesLock.Lock()
+	defer identitiesLock.Unlock()
+	id := n.identityFor()
+	id.priv = priv
+}
+
+// TrustPeer records pub as the expected Ed25519 identity of the remote graph
+// registered under name. ConnectSecure refuses to attach a port to name
+// until it has been trusted this way.
+func (n *Graph) TrustPeer(name string, pub ed25519.PublicKey) {
+	identitiesLock.Lock()
+	defer identitiesLock.Unlock()
+	id := n.identityFor()
+	id.trust[name] = pub
+}
+
+func (n *Graph) identityFor() *identity {
+	id, ok := identities[n]
+	if !ok {
+		id = &identity{trust: make(map[string]ed25519.PublicKey)}
+		identities[n] = id
+	}
+	return id
+}
+
+// ConnectSecure behaves like Connect's "remote/port" form, except the
+// underlying gRPC stream is wrapped in an authenticated, end-to-end
+// encrypted session: it refuses to proceed unless both SetIdentity has been
+// called and TrustPeer has recorded an identity for the target remote, and
+// the handshake itself fails closed if the peer's signature doesn't match
+// that trusted key.
+func (n *Graph) ConnectSecure(senderName, senderPort, receiverName, receiverPort string, bufferSize int) error {
+	remoteName, remotePort, ok := isRemoteAddr(receiverName)
+	if !ok {
+		return fmt.Errorf("connect secure: receiver '%s' must name a remote process as 'remote/port'", receiverName)
+	}
+
+	// Hold identitiesLock across both reads: SetIdentity and TrustPeer write
+	// id.priv and id.trust under the same lock from potentially concurrent
+	// callers, and id.trust is a plain map, so reading it unlocked races with
+	// a concurrent TrustPeer write.
+	identitiesLock.Lock()
+	id, ok := identities[n]
+	var priv ed25519.PrivateKey
+	var peerPub ed25519.PublicKey
+	if ok {
+		priv = id.priv
+		peerPub, ok = id.trust[remoteName]
+	}
+	identitiesLock.Unlock()
+	if priv == nil {
+		return fmt.Errorf("connect secure: call Graph.SetIdentity before ConnectSecure")
+	}
+	if !ok {
+		return fmt.Errorf("connect secure: '%s' is not a trusted peer, call Graph.TrustPeer first", remoteName)
+	}
+
+	ep, ok := n.getRemote(remoteName)
+	if !ok {
+		return fmt.Errorf("connect secure: remote '%s' not registered, call Graph.AddRemote first", remoteName)
+	}
+
+	sendAddr := parseAddress(senderName, senderPort)
+	sendPort, err := n.getProcPort(senderName, senderPort, reflect.SendDir)
+	if err != nil {
+		return fmt.Errorf("connect secure: %w", err)
+	}
+
+	port, err := ep.OpenSecurePort(context.Background(), remotePort, priv, peerPub)
+	if err != nil {
+		return fmt.Errorf("connect secure '%s/%s': %w", remoteName, remotePort, err)
+	}
+	ch := proxyChan(port, sendPort.Type().Elem(), reflect.SendDir, bufferSize)
+
+	if err := validateChanDir(sendPort.Type(), reflect.SendDir); err != nil {
+		return fmt.Errorf("connect secure '%s.%s': %w", senderName, senderPort, err)
+	}
+	if err := validateCanSet(sendPort); err != nil {
+		return fmt.Errorf("connect secure '%s.%s': %w", senderName, senderPort, err)
+	}
+	sendPort.Set(ch)
+	n.incChanListenersCount(ch)
+
+	n.connections = append(n.connections, connection{
+		src:     sendAddr,
+		tgt:     address{proc: remoteName, port: remotePort},
+		channel: ch,
+		buffer:  bufferSize,
+	})
+	return nil
+}
@@ -0,0 +1,218 @@
+package goflow
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TapOptions configures the interceptor Graph.Tap installs on a connection.
+type TapOptions struct {
+	// Drop, if set, is consulted for every message; returning true drops the
+	// message instead of forwarding it to the receiver.
+	Drop func(v interface{}) bool
+	// Delay, if set, is consulted for every message that isn't dropped; a
+	// non-zero return value holds the message back that long before it is
+	// forwarded, emulating a slow link.
+	Delay func(v interface{}) time.Duration
+}
+
+// tapMessage records one message that traversed a tapped connection.
+type tapMessage struct {
+	at    time.Time
+	value interface{}
+	bytes int
+}
+
+// Stats summarizes the traffic a TapHandle has observed.
+type Stats struct {
+	Count int
+	Bytes int64
+	P50   time.Duration
+	P99   time.Duration
+}
+
+// TapHandle controls an interceptor installed by Graph.Tap. It is safe for
+// concurrent use.
+type TapHandle struct {
+	opts TapOptions
+
+	in   reflect.Value // original connection channel, read by the interceptor
+	out  reflect.Value // channel now wired into the receiver's port
+	done chan struct{}
+
+	mu       sync.Mutex
+	recorded []tapMessage
+
+	// restore undoes the port splice when the handle is closed.
+	restore func()
+}
+
+// Messages returns every message recorded on the tapped connection so far,
+// oldest first.
+func (h *TapHandle) Messages() []interface{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]interface{}, len(h.recorded))
+	for i, m := range h.recorded {
+		out[i] = m.value
+	}
+	return out
+}
+
+// Inject sends v directly to the receiver, bypassing Drop and Delay, to
+// exercise the receiver with a synthetic message.
+func (h *TapHandle) Inject(v interface{}) error {
+	if t := reflect.TypeOf(v); t != nil && t != h.out.Type().Elem() {
+		return fmt.Errorf("tap: cannot inject %s onto a channel of %s", t, h.out.Type().Elem())
+	}
+	h.out.Send(reflect.ValueOf(v))
+	return nil
+}
+
+// Stats returns a snapshot of count, total bytes (for []byte elements) and
+// inter-arrival percentiles observed so far.
+func (h *TapHandle) Stats() Stats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := Stats{Count: len(h.recorded)}
+	if len(h.recorded) == 0 {
+		return stats
+	}
+
+	gaps := make([]time.Duration, 0, len(h.recorded)-1)
+	for i, m := range h.recorded {
+		stats.Bytes += int64(m.bytes)
+		if i > 0 {
+			gaps = append(gaps, m.at.Sub(h.recorded[i-1].at))
+		}
+	}
+	if len(gaps) == 0 {
+		return stats
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	stats.P50 = gaps[(len(gaps)-1)*50/100]
+	stats.P99 = gaps[(len(gaps)-1)*99/100]
+	return stats
+}
+
+// Close stops the interceptor and reattaches the receiver directly to the
+// connection's original channel.
+func (h *TapHandle) Close() error {
+	close(h.done)
+	h.restore()
+	return nil
+}
+
+// Tap installs an interceptor on an existing connection between senderName
+// and receiverName without changing either process's code: the receiver's
+// port is spliced onto a new channel of the same element type, and a
+// goroutine relays messages from the original channel onto it, applying
+// opts.Drop and opts.Delay and recording everything it forwards.
+func (n *Graph) Tap(senderName, senderPort, receiverName, receiverPort string, opts TapOptions) (*TapHandle, error) {
+	sendAddr := parseAddress(senderName, senderPort)
+	recvAddr := parseAddress(receiverName, receiverPort)
+
+	_, conn, err := n.findConnection(sendAddr, recvAddr)
+	if err != nil {
+		return nil, fmt.Errorf("tap: %w", err)
+	}
+
+	recvPort, err := n.getProcPort(receiverName, receiverPort, reflect.RecvDir)
+	if err != nil {
+		return nil, fmt.Errorf("tap: %w", err)
+	}
+
+	// conn.channel is the sender-bound channel. For a plain connection that's
+	// also what the receiver reads, but for a routed or replayed connection
+	// it's the dispatcher/pump's own source channel, already exclusively
+	// consumed by dispatch()/pump() — splicing in there would make relay() a
+	// second competing reader on it. The receiver's actual traffic comes from
+	// conn.recvChannel instead whenever that's set.
+	source := conn.channel
+	if conn.recvChannel.IsValid() {
+		source = conn.recvChannel
+	}
+
+	tapped := reflect.MakeChan(source.Type(), conn.buffer)
+	recvPort.Set(tapped)
+
+	h := &TapHandle{
+		opts: opts,
+		in:   source,
+		out:  tapped,
+		done: make(chan struct{}),
+		restore: func() {
+			recvPort.Set(source)
+		},
+	}
+	go h.relay()
+
+	return h, nil
+}
+
+// relay is the only goroutine that closes h.out (Inject also sends on it), so
+// there is never a double close between the input-closed path below and a
+// concurrent Close().
+func (h *TapHandle) relay() {
+	doneCase := reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(h.done)}
+	recvCase := reflect.SelectCase{Dir: reflect.SelectRecv, Chan: h.in}
+
+	for {
+		// Wait on h.done alongside h.in, so Close() stops the relay while it
+		// is idle instead of it taking the next message away from the
+		// receiver that h.restore reattached to h.in.
+		chosen, v, ok := reflect.Select([]reflect.SelectCase{doneCase, recvCase})
+		if chosen == 0 {
+			h.out.Close()
+			return
+		}
+		if !ok {
+			h.out.Close()
+			return
+		}
+
+		if h.opts.Drop != nil && h.opts.Drop(v.Interface()) {
+			continue
+		}
+		if h.opts.Delay != nil {
+			if d := h.opts.Delay(v.Interface()); d > 0 {
+				select {
+				case <-time.After(d):
+				case <-h.done:
+					h.out.Close()
+					return
+				}
+			}
+		}
+
+		h.record(v)
+
+		// A plain "select with default" doesn't make this send cancellable:
+		// once default is skipped in favor of the send case, h.out.Send still
+		// blocks unconditionally. reflect.Select genuinely races the two
+		// cases, so Close() unblocks a send that nothing will ever read
+		// (h.restore moved the receiver off h.out) instead of leaking this
+		// goroutine forever.
+		sendCase := reflect.SelectCase{Dir: reflect.SelectSend, Chan: h.out, Send: v}
+		chosen, _, _ := reflect.Select([]reflect.SelectCase{doneCase, sendCase})
+		if chosen == 0 {
+			h.out.Close()
+			return
+		}
+	}
+}
+
+func (h *TapHandle) record(v reflect.Value) {
+	m := tapMessage{at: time.Now(), value: v.Interface()}
+	if b, ok := m.value.([]byte); ok {
+		m.bytes = len(b)
+	}
+
+	h.mu.Lock()
+	h.recorded = append(h.recorded, m)
+	h.mu.Unlock()
+}
@@ -0,0 +1,51 @@
+package goflow
+
+import "testing"
+
+type tapSender struct {
+	Out chan int
+}
+
+type tapReceiver struct {
+	In chan int
+}
+
+func TestTapOnRoutedConnectionSplicesRecvChannel(t *testing.T) {
+	n := newTestGraph()
+	n.addProc("sender", &tapSender{})
+	n.addProc("r1", &tapReceiver{})
+	n.addProc("r2", &tapReceiver{})
+
+	if err := n.Connect("sender", "Out", "r1", "In"); err != nil {
+		t.Fatalf("connect r1: %v", err)
+	}
+	n.SetRouteMode("sender", "Out", RouteRoundRobin)
+	if err := n.Connect("sender", "Out", "r2", "In"); err != nil {
+		t.Fatalf("connect r2: %v", err)
+	}
+
+	// Tapping r1's leg of the fan-out must splice onto conn.recvChannel
+	// (r1's own dedicated channel), not conn.channel (the dispatcher's
+	// srcChan, already exclusively read by dispatch()).
+	h, err := n.Tap("sender", "Out", "r1", "In", TapOptions{})
+	if err != nil {
+		t.Fatalf("tap: %v", err)
+	}
+	defer h.Close()
+
+	sender := n.procs["sender"].(*tapSender)
+	r1 := n.procs["r1"].(*tapReceiver)
+
+	go func() { sender.Out <- 1 }()
+
+	// With round-robin starting at index 0, the first packet goes to r1.
+	got := <-r1.In
+	if got != 1 {
+		t.Fatalf("r1.In = %d, want 1", got)
+	}
+
+	msgs := h.Messages()
+	if len(msgs) != 1 || msgs[0] != 1 {
+		t.Fatalf("Messages() = %v, want [1]", msgs)
+	}
+}
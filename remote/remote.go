@@ -0,0 +1,261 @@
+// Package remote lets a Graph's ports span process and machine boundaries.
+// A host registers a peer with NewEndpoint and then opens a named port stream
+// to it; a Server on the other end demultiplexes incoming streams onto local
+// handlers by port name. Each stream carries gob-encoded channel elements
+// over a single gRPC bidirectional-streaming RPC, so ordinary gRPC flow
+// control provides backpressure for free: a Send blocks once the peer stops
+// reading.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// rawCodec passes Frame bytes straight through, so the service can be served
+// without a .proto-generated message type.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("remote: rawCodec cannot marshal %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("remote: rawCodec cannot unmarshal into %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) String() string { return "raw" }
+
+// streamDesc is the generic bidi-streaming RPC every port is multiplexed
+// over; the port name travels inside each frame rather than as part of the
+// method name, so a single RPC serves every port of every process.
+var streamDesc = grpc.StreamDesc{
+	StreamName:    "Channel",
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// frame is a single length-prefixed packet exchanged over a stream. port
+// identifies which local process/port the payload belongs to so one stream
+// can carry traffic for many ports; data is the gob encoding of the
+// channel's element value, or nil for a close notification.
+type frame struct {
+	Port   string
+	Data   []byte
+	Closed bool
+}
+
+func encodeFrame(f frame) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(f); err != nil {
+		return nil, fmt.Errorf("remote: encode frame: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeFrame(b []byte) (frame, error) {
+	var f frame
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&f); err != nil {
+		return frame{}, fmt.Errorf("remote: decode frame: %w", err)
+	}
+	return f, nil
+}
+
+// rawStream is the subset of grpc.ClientStream/grpc.ServerStream that frame
+// forwarding needs.
+type rawStream interface {
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+}
+
+func sendFrame(s rawStream, f frame) error {
+	b, err := encodeFrame(f)
+	if err != nil {
+		return err
+	}
+	return s.SendMsg(&b)
+}
+
+func recvFrame(s rawStream) (frame, error) {
+	var b []byte
+	if err := s.RecvMsg(&b); err != nil {
+		return frame{}, err
+	}
+	return decodeFrame(b)
+}
+
+// Endpoint is a remote goflow host that local processes can connect to or
+// accept connections from, registered on a Graph via Graph.AddRemote.
+type Endpoint struct {
+	Name string
+	Addr string
+
+	mu   sync.Mutex
+	conn *grpc.ClientConn
+}
+
+// NewEndpoint describes a remote goflow host reachable at addr.
+func NewEndpoint(name, addr string) *Endpoint {
+	return &Endpoint{Name: name, Addr: addr}
+}
+
+func (e *Endpoint) dial(ctx context.Context) (*grpc.ClientConn, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn != nil {
+		return e.conn, nil
+	}
+	conn, err := grpc.DialContext(ctx, e.Addr,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(grpc.CallCustomCodec(rawCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("remote: dial %s: %w", e.Addr, err)
+	}
+	e.conn = conn
+	return conn, nil
+}
+
+// OpenPort dials the endpoint if it isn't already connected, opens a new
+// stream on that connection, and returns a Port bound to the given remote
+// port name. Each call opens its own stream.
+func (e *Endpoint) OpenPort(ctx context.Context, port string) (*Port, error) {
+	conn, err := e.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := conn.NewStream(ctx, &streamDesc, "/goflow.remote.Channel/Stream")
+	if err != nil {
+		return nil, fmt.Errorf("remote: open stream to %s: %w", e.Addr, err)
+	}
+	return &Port{name: port, stream: stream}, nil
+}
+
+// Port forwards values of a single channel element type across a remote
+// stream, blocking on Send/Recv exactly like the local channel it proxies so
+// the remote peer's flow control becomes this port's backpressure.
+type Port struct {
+	name   string
+	stream rawStream
+}
+
+// Send gob-encodes v and writes it to the remote peer, blocking until the
+// stream's flow-control window has room.
+func (p *Port) Send(v reflect.Value) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).EncodeValue(v); err != nil {
+		return fmt.Errorf("remote: encode value for port %s: %w", p.name, err)
+	}
+	return sendFrame(p.stream, frame{Port: p.name, Data: buf.Bytes()})
+}
+
+// Recv blocks until a value addressed to this port arrives and decodes it
+// into a new reflect.Value of elemType.
+func (p *Port) Recv(elemType reflect.Type) (reflect.Value, error) {
+	f, err := recvFrame(p.stream)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return DecodeValue(f.Data, elemType)
+}
+
+// DecodeValue gob-decodes data into a new reflect.Value of elemType. It is
+// exported so a Server's PortHandler, which only sees raw frame bytes, can
+// decode them the same way Port.Recv does.
+func DecodeValue(data []byte, elemType reflect.Type) (reflect.Value, error) {
+	v := reflect.New(elemType)
+	if err := gob.NewDecoder(bytes.NewReader(data)).DecodeValue(v.Elem()); err != nil {
+		return reflect.Value{}, fmt.Errorf("remote: decode value: %w", err)
+	}
+	return v.Elem(), nil
+}
+
+// Close tells the peer this side is done with the port.
+func (p *Port) Close() error {
+	return sendFrame(p.stream, frame{Port: p.name, Closed: true})
+}
+
+// PortHandler forwards demultiplexed frames for a single remote port name.
+// The server calls it with every frame's payload bytes and a closed flag;
+// handlers are installed with Server.Handle, one per locally exposed port.
+type PortHandler func(data []byte, closed bool) error
+
+// Server demultiplexes incoming streams onto local port handlers by frame
+// port name, so a single listener can back many proxy processes.
+type Server struct {
+	grpcServer *grpc.Server
+
+	mu       sync.RWMutex
+	handlers map[string]PortHandler
+}
+
+// NewServer creates a Server with no ports registered yet; call Handle for
+// each local process/port that should accept remote connections before
+// calling Serve.
+func NewServer() *Server {
+	s := &Server{handlers: make(map[string]PortHandler)}
+	s.grpcServer = grpc.NewServer(grpc.CustomCodec(rawCodec{}))
+	s.grpcServer.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "goflow.remote.Channel",
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Stream",
+			Handler:       s.stream,
+			ServerStreams: true,
+			ClientStreams: true,
+		}},
+	}, nil)
+	return s
+}
+
+// Handle registers fn to receive every frame addressed to port.
+func (s *Server) Handle(port string, fn PortHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[port] = fn
+}
+
+func (s *Server) stream(srv interface{}, stream grpc.ServerStream) error {
+	for {
+		f, err := recvFrame(stream)
+		if err != nil {
+			return err
+		}
+		s.mu.RLock()
+		fn, ok := s.handlers[f.Port]
+		s.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("remote: no handler registered for port %q", f.Port)
+		}
+		if err := fn(f.Data, f.Closed); err != nil {
+			return err
+		}
+	}
+}
+
+// Serve accepts connections on lis until the listener is closed.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpcServer.Serve(lis)
+}
+
+// Stop gracefully shuts the server down, draining in-flight streams.
+func (s *Server) Stop() {
+	s.grpcServer.GracefulStop()
+}
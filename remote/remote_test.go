@@ -0,0 +1,98 @@
+package remote
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRawCodecRoundTrip(t *testing.T) {
+	var c rawCodec
+	if got := c.String(); got != "raw" {
+		t.Fatalf("String() = %q, want %q", got, "raw")
+	}
+
+	in := []byte("hello frame")
+	marshaled, err := c.Marshal(&in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var out []byte
+	if err := c.Unmarshal(marshaled, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if string(out) != string(in) {
+		t.Fatalf("Unmarshal roundtrip = %q, want %q", out, in)
+	}
+}
+
+func TestRawCodecRejectsWrongType(t *testing.T) {
+	var c rawCodec
+	if _, err := c.Marshal("not a *[]byte"); err == nil {
+		t.Fatal("Marshal: expected error for non-[]byte pointer, got nil")
+	}
+	var notBytes int
+	if err := c.Unmarshal([]byte("x"), &notBytes); err == nil {
+		t.Fatal("Unmarshal: expected error for non-[]byte pointer, got nil")
+	}
+}
+
+func TestEncodeDecodeFrameRoundTrip(t *testing.T) {
+	want := frame{Port: "In", Data: []byte{1, 2, 3}, Closed: true}
+
+	b, err := encodeFrame(want)
+	if err != nil {
+		t.Fatalf("encodeFrame: %v", err)
+	}
+	got, err := decodeFrame(b)
+	if err != nil {
+		t.Fatalf("decodeFrame: %v", err)
+	}
+	if got.Port != want.Port || got.Closed != want.Closed || string(got.Data) != string(want.Data) {
+		t.Fatalf("decodeFrame = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeValueRoundTrip(t *testing.T) {
+	p := &Port{name: "Out"}
+	var sendBuf []byte
+	fakeStream := &recordingStream{sent: &sendBuf}
+	p.stream = fakeStream
+
+	if err := p.Send(reflect.ValueOf("a packet")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	f, err := decodeFrame(sendBuf)
+	if err != nil {
+		t.Fatalf("decodeFrame: %v", err)
+	}
+	v, err := DecodeValue(f.Data, reflect.TypeOf(""))
+	if err != nil {
+		t.Fatalf("DecodeValue: %v", err)
+	}
+	if v.Interface().(string) != "a packet" {
+		t.Fatalf("DecodeValue = %v, want %q", v.Interface(), "a packet")
+	}
+}
+
+// recordingStream is a rawStream that captures the last message sent to it
+// and, if primed via recv, replays a canned message back.
+type recordingStream struct {
+	sent *[]byte
+	recv [][]byte
+}
+
+func (s *recordingStream) SendMsg(m interface{}) error {
+	b := m.(*[]byte)
+	*s.sent = append([]byte(nil), *b...)
+	return nil
+}
+
+func (s *recordingStream) RecvMsg(m interface{}) error {
+	b := m.(*[]byte)
+	next := s.recv[0]
+	s.recv = s.recv[1:]
+	*b = next
+	return nil
+}
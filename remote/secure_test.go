@@ -0,0 +1,174 @@
+package remote
+
+import (
+	"crypto/ed25519"
+	"reflect"
+	"testing"
+)
+
+// pipeStream connects to a peer pipeStream so the two ends of handshake() can
+// run against each other without a real network connection.
+type pipeStream struct {
+	out chan []byte
+	in  chan []byte
+}
+
+func newPipe() (a, b *pipeStream) {
+	ab := make(chan []byte, 4)
+	ba := make(chan []byte, 4)
+	return &pipeStream{out: ab, in: ba}, &pipeStream{out: ba, in: ab}
+}
+
+func (p *pipeStream) SendMsg(m interface{}) error {
+	b := m.(*[]byte)
+	p.out <- append([]byte(nil), *b...)
+	return nil
+}
+
+func (p *pipeStream) RecvMsg(m interface{}) error {
+	b := m.(*[]byte)
+	*b = <-p.in
+	return nil
+}
+
+func TestHandshakeDerivesMatchingKeys(t *testing.T) {
+	aPub, aPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key A: %v", err)
+	}
+	bPub, bPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key B: %v", err)
+	}
+
+	streamA, streamB := newPipe()
+
+	type result struct {
+		aead interface {
+			Seal([]byte, []byte, []byte, []byte) []byte
+			Open([]byte, []byte, []byte, []byte) ([]byte, error)
+		}
+		err error
+	}
+	resultsA := make(chan result, 1)
+	resultsB := make(chan result, 1)
+
+	go func() {
+		aead, err := handshake(streamA, aPriv, bPub)
+		resultsA <- result{aead, err}
+	}()
+	go func() {
+		aead, err := handshake(streamB, bPriv, aPub)
+		resultsB <- result{aead, err}
+	}()
+
+	ra := <-resultsA
+	rb := <-resultsB
+	if ra.err != nil {
+		t.Fatalf("handshake A: %v", ra.err)
+	}
+	if rb.err != nil {
+		t.Fatalf("handshake B: %v", rb.err)
+	}
+
+	plaintext := []byte("secret payload")
+	sealed := ra.aead.Seal(nil, make([]byte, 12), plaintext, nil)
+	opened, err := rb.aead.Open(nil, make([]byte, 12), sealed, nil)
+	if err != nil {
+		t.Fatalf("Open with peer-derived key: %v", err)
+	}
+	if string(opened) != string(plaintext) {
+		t.Fatalf("decrypted %q, want %q", opened, plaintext)
+	}
+}
+
+func TestHandshakeRejectsWrongPeerKey(t *testing.T) {
+	aPub, aPriv, _ := ed25519.GenerateKey(nil)
+	_, bPriv, _ := ed25519.GenerateKey(nil)
+	wrongPub, _, _ := ed25519.GenerateKey(nil)
+
+	streamA, streamB := newPipe()
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := handshake(streamA, aPriv, wrongPub)
+		errs <- err
+	}()
+	go func() {
+		_, err := handshake(streamB, bPriv, aPub)
+		errs <- err
+	}()
+
+	first := <-errs
+	second := <-errs
+	if first == nil && second == nil {
+		t.Fatal("expected at least one side to reject the mismatched peer key, got no errors")
+	}
+}
+
+func TestNonceRejectsOverflow(t *testing.T) {
+	if _, err := nonce(0); err == nil {
+		t.Fatal("nonce(0) should fail closed instead of reusing the zero nonce")
+	}
+	n, err := nonce(1)
+	if err != nil {
+		t.Fatalf("nonce(1): %v", err)
+	}
+	if len(n) != 12 {
+		t.Fatalf("nonce length = %d, want 12", len(n))
+	}
+}
+
+func TestLessBytes(t *testing.T) {
+	if !lessBytes([]byte{1, 2}, []byte{1, 3}) {
+		t.Fatal("expected {1,2} < {1,3}")
+	}
+	if lessBytes([]byte{1, 3}, []byte{1, 2}) {
+		t.Fatal("expected {1,3} not < {1,2}")
+	}
+}
+
+func TestSecurePortSendRecvRoundTrip(t *testing.T) {
+	aPub, aPriv, _ := ed25519.GenerateKey(nil)
+	bPub, bPriv, _ := ed25519.GenerateKey(nil)
+	streamA, streamB := newPipe()
+
+	doneA := make(chan error, 1)
+	doneB := make(chan error, 1)
+	var portA, portB *SecurePort
+
+	go func() {
+		aead, err := handshake(streamA, aPriv, bPub)
+		if err == nil {
+			portA = &SecurePort{Port: &Port{name: "Out", stream: streamA}, aead: aead}
+		}
+		doneA <- err
+	}()
+	go func() {
+		aead, err := handshake(streamB, bPriv, aPub)
+		if err == nil {
+			portB = &SecurePort{Port: &Port{name: "Out", stream: streamB}, aead: aead}
+		}
+		doneB <- err
+	}()
+	if err := <-doneA; err != nil {
+		t.Fatalf("handshake A: %v", err)
+	}
+	if err := <-doneB; err != nil {
+		t.Fatalf("handshake B: %v", err)
+	}
+
+	sendErr := make(chan error, 1)
+	go func() { sendErr <- portA.Send(reflect.ValueOf("hi")) }()
+	if err := <-sendErr; err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	v, err := portB.Recv(reflect.TypeOf(""))
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if v.Interface().(string) != "hi" {
+		t.Fatalf("Recv = %v, want %q", v.Interface(), "hi")
+	}
+}